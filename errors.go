@@ -0,0 +1,7 @@
+package containerd
+
+import "errors"
+
+// ErrNotSupported is returned by operations that have no implementation
+// on the current platform.
+var ErrNotSupported = errors.New("containerd: not supported on this platform")
@@ -0,0 +1,138 @@
+package containerd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// MountFlags maps the fstab-style mount options this package understands
+// to the unix.MNT_* bit Darwin's mount(2) expects. It is exported so
+// that callers which need to recognize additional options can extend
+// it.
+var MountFlags = map[string]struct {
+	Clear bool
+	Flag  int
+}{
+	"noatime": {false, unix.MNT_NOATIME},
+	"noexec":  {false, unix.MNT_NOEXEC},
+	"nosuid":  {false, unix.MNT_NOSUID},
+	"ro":      {false, unix.MNT_RDONLY},
+	"rw":      {true, unix.MNT_RDONLY},
+	"sync":    {false, unix.MNT_SYNCHRONOUS},
+}
+
+// MountAllExec mounts every entry in mounts by shelling out to the
+// "mount" binary, in order, stopping at the first error. Most callers
+// want MountAll instead; this is kept for the few that specifically
+// want CLI mount(8) behavior.
+func MountAllExec(mounts ...Mount) error {
+	for _, mount := range mounts {
+		cmd := exec.Command("mount", MountCommand(mount)[1:]...)
+		cmd.Stderr = os.Stderr
+		cmd.Stdout = os.Stdout
+
+		if err := cmd.Run(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MountAll mounts every entry in mounts in-process via MountFS, in
+// order, stopping at the first error. Unlike the Linux implementation,
+// mounts are not parallelized and a failure partway through does not
+// unwind the mounts that already succeeded, since Unmount is not
+// implemented on this platform.
+func MountAll(mounts ...Mount) error {
+	return MountAllContext(context.Background(), mounts...)
+}
+
+// MountAllContext is MountAll with ctx checked between mounts so a
+// long-running sequence can be abandoned early.
+func MountAllContext(ctx context.Context, mounts ...Mount) error {
+	for _, m := range mounts {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := m.Mount(m.Target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MountFS performs the mount(2) syscall for every entry in mounts, in
+// order, targeting target. Darwin has no MS_BIND-style dedicated bind
+// mount, so "bind"/"rbind" sources are passed through via the args
+// struct below and rely on the underlying filesystem supporting them.
+func MountFS(mounts []Mount, target string) error {
+	for _, m := range mounts {
+		flags, data := parseMountOptions(m.Options)
+		argp, err := mountArgs(m.Source, data)
+		if err != nil {
+			return err
+		}
+		if err := unix.Mount(m.Type, target, flags, argp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sourceArgs mirrors the layout the simple local filesystems in
+// Darwin's kernel (e.g. msdosfs_args, cd9660_args) use for their first
+// fields: a NUL-terminated source path followed by a NUL-terminated
+// options string. Filesystems with a richer argument struct (e.g. NFS,
+// SMB) aren't representable generically; use MountAllExec for those.
+type sourceArgs struct {
+	fspec   *byte
+	options *byte
+}
+
+// mountArgs builds the data argument for unix.Mount from a mount's
+// source and its leftover (non-flag) options, so that both actually
+// reach the kernel instead of being dropped on the floor.
+func mountArgs(source, options string) (unsafe.Pointer, error) {
+	if source == "" && options == "" {
+		return nil, nil
+	}
+	fspec, err := unix.BytePtrFromString(source)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := unix.BytePtrFromString(options)
+	if err != nil {
+		return nil, err
+	}
+	return unsafe.Pointer(&sourceArgs{fspec: fspec, options: opts}), nil
+}
+
+// parseMountOptions takes fstab style mount options and parses them for
+// use with a standard mount() syscall.
+func parseMountOptions(options []string) (int, string) {
+	var (
+		flag int
+		data []string
+	)
+	for _, o := range options {
+		// If the option does not exist in the flags table or the flag
+		// is not supported on the platform,
+		// then it is a data value for a specific fs type
+		if f, exists := MountFlags[o]; exists && f.Flag != 0 {
+			if f.Clear {
+				flag &= ^f.Flag
+			} else {
+				flag |= f.Flag
+			}
+		} else {
+			data = append(data, o)
+		}
+	}
+	return flag, strings.Join(data, ",")
+}
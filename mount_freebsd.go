@@ -0,0 +1,161 @@
+package containerd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// MountFlags maps the fstab-style mount options this package understands
+// to the unix.MNT_* bit FreeBSD's mount(2) expects. It is exported so
+// that callers which need to recognize additional options can extend
+// it.
+var MountFlags = map[string]struct {
+	Clear bool
+	Flag  int
+}{
+	"noatime": {false, unix.MNT_NOATIME},
+	"noexec":  {false, unix.MNT_NOEXEC},
+	"nosuid":  {false, unix.MNT_NOSUID},
+	"ro":      {false, unix.MNT_RDONLY},
+	"rw":      {true, unix.MNT_RDONLY},
+	"sync":    {false, unix.MNT_SYNCHRONOUS},
+}
+
+// MountAllExec mounts every entry in mounts by shelling out to the
+// "mount" binary, in order, stopping at the first error. Most callers
+// want MountAll instead; this is kept for the few that specifically
+// want CLI mount(8) behavior.
+func MountAllExec(mounts ...Mount) error {
+	for _, mount := range mounts {
+		cmd := exec.Command("mount", MountCommand(mount)[1:]...)
+		cmd.Stderr = os.Stderr
+		cmd.Stdout = os.Stdout
+
+		if err := cmd.Run(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MountAll mounts every entry in mounts in-process via MountFS, in
+// order, stopping at the first error. Unlike the Linux implementation,
+// mounts are not parallelized and a failure partway through does not
+// unwind the mounts that already succeeded, since Unmount is not
+// implemented on this platform.
+func MountAll(mounts ...Mount) error {
+	return MountAllContext(context.Background(), mounts...)
+}
+
+// MountAllContext is MountAll with ctx checked between mounts so a
+// long-running sequence can be abandoned early.
+func MountAllContext(ctx context.Context, mounts ...Mount) error {
+	for _, m := range mounts {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := m.Mount(m.Target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MountFS performs the mount syscall for every entry in mounts, in
+// order, targeting target. FreeBSD has no classic two-argument
+// unix.Mount in golang.org/x/sys/unix; everything goes through
+// nmount(2), which takes its source, target, and options as a flat
+// array of name/value pairs.
+func MountFS(mounts []Mount, target string) error {
+	for _, m := range mounts {
+		flags, data := parseMountOptions(m.Options)
+
+		args := map[string]string{
+			"fstype": m.Type,
+			"fspath": target,
+		}
+		if m.Source != "" {
+			args["from"] = m.Source
+		}
+		for _, kv := range strings.Split(data, ",") {
+			if kv == "" {
+				continue
+			}
+			if k, v, ok := strings.Cut(kv, "="); ok {
+				args[k] = v
+			} else {
+				args[kv] = ""
+			}
+		}
+
+		if err := nmount(args, flags); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nmount issues the nmount(2) syscall with args encoded as the
+// alternating name/value iovec array the kernel expects.
+func nmount(args map[string]string, flags int) error {
+	iov := make([]unix.Iovec, 0, len(args)*2)
+	for k, v := range args {
+		kiov, err := iovecForString(k)
+		if err != nil {
+			return err
+		}
+		viov, err := iovecForString(v)
+		if err != nil {
+			return err
+		}
+		iov = append(iov, kiov, viov)
+	}
+
+	_, _, errno := unix.Syscall(unix.SYS_NMOUNT, uintptr(unsafe.Pointer(&iov[0])), uintptr(len(iov)), uintptr(flags))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// iovecForString builds an Iovec pointing at a NUL-terminated copy of
+// s, suitable for passing to nmount(2).
+func iovecForString(s string) (unix.Iovec, error) {
+	b, err := unix.BytePtrFromString(s)
+	if err != nil {
+		return unix.Iovec{}, err
+	}
+	iov := unix.Iovec{Base: b}
+	iov.SetLen(len(s) + 1)
+	return iov, nil
+}
+
+// parseMountOptions takes fstab style mount options and parses them for
+// use with a standard mount() syscall.
+func parseMountOptions(options []string) (int, string) {
+	var (
+		flag int
+		data []string
+	)
+	for _, o := range options {
+		// If the option does not exist in the flags table or the flag
+		// is not supported on the platform,
+		// then it is a data value for a specific fs type
+		if f, exists := MountFlags[o]; exists && f.Flag != 0 {
+			if f.Clear {
+				flag &= ^f.Flag
+			} else {
+				flag |= f.Flag
+			}
+		} else {
+			data = append(data, o)
+		}
+	}
+	return flag, strings.Join(data, ",")
+}
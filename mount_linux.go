@@ -0,0 +1,204 @@
+package containerd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// MountFlags maps fstab-style mount option names to the syscall.MS_*
+// bit they toggle, along with whether the option clears the bit rather
+// than setting it. It is exported so that callers which need to
+// recognize additional, filesystem-specific options can extend it.
+var MountFlags = map[string]struct {
+	Clear bool
+	Flag  int
+}{
+	"async":         {true, syscall.MS_SYNCHRONOUS},
+	"atime":         {true, syscall.MS_NOATIME},
+	"bind":          {false, syscall.MS_BIND},
+	"defaults":      {false, 0},
+	"dev":           {true, syscall.MS_NODEV},
+	"diratime":      {true, syscall.MS_NODIRATIME},
+	"dirsync":       {false, syscall.MS_DIRSYNC},
+	"exec":          {true, syscall.MS_NOEXEC},
+	"mand":          {false, syscall.MS_MANDLOCK},
+	"noatime":       {false, syscall.MS_NOATIME},
+	"nodev":         {false, syscall.MS_NODEV},
+	"nodiratime":    {false, syscall.MS_NODIRATIME},
+	"noexec":        {false, syscall.MS_NOEXEC},
+	"nomand":        {true, syscall.MS_MANDLOCK},
+	"norelatime":    {true, syscall.MS_RELATIME},
+	"nostrictatime": {true, syscall.MS_STRICTATIME},
+	"nosuid":        {false, syscall.MS_NOSUID},
+	"private":       {false, syscall.MS_PRIVATE},
+	"rbind":         {false, syscall.MS_BIND | syscall.MS_REC},
+	"relatime":      {false, syscall.MS_RELATIME},
+	"remount":       {false, syscall.MS_REMOUNT},
+	"ro":            {false, syscall.MS_RDONLY},
+	"rprivate":      {false, syscall.MS_PRIVATE | syscall.MS_REC},
+	"rshared":       {false, syscall.MS_SHARED | syscall.MS_REC},
+	"rslave":        {false, syscall.MS_SLAVE | syscall.MS_REC},
+	"runbindable":   {false, syscall.MS_UNBINDABLE | syscall.MS_REC},
+	"rw":            {true, syscall.MS_RDONLY},
+	"shared":        {false, syscall.MS_SHARED},
+	"slave":         {false, syscall.MS_SLAVE},
+	"strictatime":   {false, syscall.MS_STRICTATIME},
+	"suid":          {true, syscall.MS_NOSUID},
+	"sync":          {false, syscall.MS_SYNCHRONOUS},
+	"unbindable":    {false, syscall.MS_UNBINDABLE},
+}
+
+// propagationFlags is the subset of MountFlags bits that set mount
+// propagation rather than per-mount behavior. The kernel rejects a
+// mount(2) call that mixes propagation flags with most other flags, so
+// they must always be applied in their own syscall.
+const propagationFlags = syscall.MS_SHARED | syscall.MS_PRIVATE | syscall.MS_SLAVE | syscall.MS_UNBINDABLE
+
+// MountAllExec mounts every entry in mounts by shelling out to the
+// "mount" binary, in order, stopping at the first error. Most callers
+// want MountAll instead; this is kept for the few that specifically
+// want CLI mount(8) behavior (e.g. its fstype auto-detection).
+func MountAllExec(mounts ...Mount) error {
+	for _, mount := range mounts {
+		cmd := exec.Command("mount", MountCommand(mount)[1:]...)
+		cmd.Stderr = os.Stderr
+		cmd.Stdout = os.Stdout
+
+		if err := cmd.Run(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MountFS performs the mount syscall for every entry in mounts, in
+// order, targeting target.
+func MountFS(mounts []Mount, target string) error {
+	for _, m := range mounts {
+		if err := mountOne(m, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mountOne issues the syscall(s) needed to satisfy m, bind mounts and
+// propagation changes included.
+//
+// The kernel only honors MS_BIND (and MS_REC) on the initial mount(2)
+// call for a bind mount; any other flags such as MS_RDONLY or
+// MS_NOEXEC are silently ignored unless reapplied via a follow-up
+// MS_REMOUNT|MS_BIND call. Propagation flags (MS_SHARED, MS_PRIVATE,
+// MS_SLAVE, MS_UNBINDABLE) can't be combined with most other flags at
+// all and always need their own third call.
+func mountOne(m Mount, target string) error {
+	flags, propagation, data := parseMountOptions(m.Options)
+	bind := flags&syscall.MS_BIND != 0
+
+	initial := flags
+	if bind {
+		initial = flags & (syscall.MS_BIND | syscall.MS_REC)
+	}
+	if err := syscall.Mount(m.Source, target, m.Type, uintptr(initial), data); err != nil {
+		return err
+	}
+
+	if bind {
+		remount := flags &^ (syscall.MS_BIND | syscall.MS_REC)
+		if remount != 0 {
+			// If the source is itself a bind mount of a locked
+			// filesystem (e.g. a read-only /proc/sys), the kernel
+			// rejects a remount that doesn't also carry the source's
+			// own effective flags (EPERM). Fold those in.
+			sourceFlags, err := effectiveMountFlags(m.Source)
+			if err != nil {
+				return err
+			}
+			if err := syscall.Mount("", target, "", uintptr(syscall.MS_REMOUNT|syscall.MS_BIND)|uintptr(remount|sourceFlags), ""); err != nil {
+				return err
+			}
+		}
+	}
+
+	if propagation != 0 {
+		if err := syscall.Mount("", target, "", uintptr(propagation), ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// effectiveMountFlags looks up the mount flags currently in effect for
+// source by scanning /proc/self/mountinfo, so that a remount of a bind
+// mount taken from source can preserve them. source doesn't need to be
+// a mount point itself: most bind sources are a subdirectory of one,
+// so this finds the containing mount — the entry whose Mountpoint is
+// the longest ancestor-or-equal prefix of source — and parses its
+// per-mount options.
+func effectiveMountFlags(source string) (int, error) {
+	var containing *MountInfo
+	_, err := GetMounts(func(mi *MountInfo) (skip, stop bool) {
+		if isMountAncestor(mi.Mountpoint, source) {
+			if containing == nil || len(mi.Mountpoint) > len(containing.Mountpoint) {
+				containing = mi
+			}
+		}
+		return true, false
+	})
+	if err != nil {
+		return 0, err
+	}
+	if containing == nil {
+		return 0, nil
+	}
+	flags, _, _ := parseMountOptions(strings.Split(containing.Options, ","))
+	return flags, nil
+}
+
+// isMountAncestor reports whether mountpoint is path itself, or a
+// directory above it.
+func isMountAncestor(mountpoint, path string) bool {
+	mountpoint = filepath.Clean(mountpoint)
+	path = filepath.Clean(path)
+	if mountpoint == path {
+		return true
+	}
+	sep := string(filepath.Separator)
+	return strings.HasPrefix(path, strings.TrimSuffix(mountpoint, sep)+sep)
+}
+
+// parseMountOptions takes fstab style mount options and parses them
+// for use with mount(2), separating out propagation flags (which the
+// kernel requires to be set via their own syscall) from the rest.
+func parseMountOptions(options []string) (flags int, propagation int, data string) {
+	var fields []string
+	for _, o := range options {
+		// If the option does not exist in the flags table or the flag
+		// is not supported on the platform,
+		// then it is a data value for a specific fs type
+		f, exists := MountFlags[o]
+		if !exists || f.Flag == 0 {
+			fields = append(fields, o)
+			continue
+		}
+		if f.Flag&propagationFlags != 0 {
+			if f.Clear {
+				propagation &= ^f.Flag
+			} else {
+				propagation |= f.Flag
+			}
+			continue
+		}
+		if f.Clear {
+			flags &= ^f.Flag
+		} else {
+			flags |= f.Flag
+		}
+	}
+	return flags, propagation, strings.Join(fields, ",")
+}
@@ -0,0 +1,43 @@
+package containerd
+
+import "testing"
+
+func TestParseMountOptions(t *testing.T) {
+	flags, propagation, data := parseMountOptions([]string{"rbind", "ro", "noatime", "shared", "size=64m"})
+
+	wantFlags := MountFlags["rbind"].Flag | MountFlags["ro"].Flag | MountFlags["noatime"].Flag
+	if flags != wantFlags {
+		t.Errorf("flags = %#x, want %#x", flags, wantFlags)
+	}
+	if propagation != MountFlags["shared"].Flag {
+		t.Errorf("propagation = %#x, want %#x", propagation, MountFlags["shared"].Flag)
+	}
+	if data != "size=64m" {
+		t.Errorf("data = %q, want %q", data, "size=64m")
+	}
+}
+
+func TestParseMountOptionsClear(t *testing.T) {
+	flags, _, _ := parseMountOptions([]string{"noatime", "atime"})
+	if flags != 0 {
+		t.Errorf("flags = %#x, want 0 (atime should clear noatime's bit)", flags)
+	}
+}
+
+func TestIsMountAncestor(t *testing.T) {
+	cases := []struct {
+		mountpoint, path string
+		want             bool
+	}{
+		{"/mnt", "/mnt", true},
+		{"/mnt", "/mnt/sub", true},
+		{"/mnt", "/mnt/sub/deeper", true},
+		{"/mnt", "/mntother", false},
+		{"/mnt", "/other", false},
+	}
+	for _, c := range cases {
+		if got := isMountAncestor(c.mountpoint, c.path); got != c.want {
+			t.Errorf("isMountAncestor(%q, %q) = %v, want %v", c.mountpoint, c.path, got, c.want)
+		}
+	}
+}
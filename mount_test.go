@@ -0,0 +1,21 @@
+package containerd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMountCommand(t *testing.T) {
+	m := Mount{
+		Type:    "EXT4",
+		Source:  "/dev/sda1",
+		Target:  "/mnt",
+		Options: []string{"ro", "noatime"},
+	}
+
+	got := MountCommand(m)
+	want := []string{"mount", "-t", "ext4", "/dev/sda1", "/mnt", "-o", "ro,noatime"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("MountCommand(%+v) = %v, want %v", m, got, want)
+	}
+}
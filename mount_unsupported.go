@@ -0,0 +1,26 @@
+//go:build !linux && !freebsd && !darwin && !windows
+// +build !linux,!freebsd,!darwin,!windows
+
+package containerd
+
+import "context"
+
+// MountAllExec is not supported on this platform.
+func MountAllExec(mounts ...Mount) error {
+	return ErrNotSupported
+}
+
+// MountAll is not supported on this platform.
+func MountAll(mounts ...Mount) error {
+	return ErrNotSupported
+}
+
+// MountAllContext is not supported on this platform.
+func MountAllContext(ctx context.Context, mounts ...Mount) error {
+	return ErrNotSupported
+}
+
+// MountFS is not supported on this platform.
+func MountFS(mounts []Mount, target string) error {
+	return ErrNotSupported
+}
@@ -0,0 +1,23 @@
+package containerd
+
+import "context"
+
+// MountAllExec is not supported on Windows.
+func MountAllExec(mounts ...Mount) error {
+	return ErrNotSupported
+}
+
+// MountAll is not supported on Windows.
+func MountAll(mounts ...Mount) error {
+	return ErrNotSupported
+}
+
+// MountAllContext is not supported on Windows.
+func MountAllContext(ctx context.Context, mounts ...Mount) error {
+	return ErrNotSupported
+}
+
+// MountFS is not supported on Windows.
+func MountFS(mounts []Mount, target string) error {
+	return ErrNotSupported
+}
@@ -0,0 +1,124 @@
+package containerd
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// MountAll mounts every entry in mounts in-process via MountFS,
+// mounting independent targets concurrently, and unmounts everything
+// that succeeded, in reverse order, if any entry fails.
+func MountAll(mounts ...Mount) error {
+	return MountAllContext(context.Background(), mounts...)
+}
+
+// MountAllContext is MountAll with ctx controlling cancellation of the
+// overall operation. This is for long-running mounts (e.g. NFS with
+// "hard,intr") that may need to be abandoned; an in-flight mount(2)
+// syscall cannot itself be interrupted, so cancellation is only
+// observed between batches.
+func MountAllContext(ctx context.Context, mounts ...Mount) error {
+	var (
+		mu      sync.Mutex
+		mounted []Mount
+	)
+	rollback := func() {
+		for i := len(mounted) - 1; i >= 0; i-- {
+			// Best effort: we're already unwinding a failure.
+			_ = Unmount(mounted[i].Target, syscall.MNT_DETACH)
+		}
+	}
+
+	for _, batch := range independentBatches(mounts) {
+		if err := ctx.Err(); err != nil {
+			rollback()
+			return err
+		}
+
+		var (
+			wg       sync.WaitGroup
+			errOnce  sync.Once
+			firstErr error
+		)
+		for _, m := range batch {
+			wg.Add(1)
+			go func(m Mount) {
+				defer wg.Done()
+				if err := m.Mount(m.Target); err != nil {
+					errOnce.Do(func() { firstErr = err })
+					return
+				}
+				mu.Lock()
+				mounted = append(mounted, m)
+				mu.Unlock()
+			}(m)
+		}
+		wg.Wait()
+
+		if firstErr != nil {
+			rollback()
+			return firstErr
+		}
+	}
+	return nil
+}
+
+// independentBatches groups mounts into ordered batches such that,
+// within a batch, no mount's target is an ancestor or descendant of
+// another's — making every mount in a batch safe to perform
+// concurrently. Batches themselves must still be applied in order,
+// since a later batch may target a path inside an earlier one.
+//
+// This only reasons about Target paths. If one mount's Source is
+// another mount's Target (e.g. a submount bind-sourced from a path a
+// sibling entry is about to populate), the two can still land in the
+// same batch and race. Callers with that kind of producer/consumer
+// dependency between mounts must serialize them across separate
+// MountAll/MountAllContext calls themselves; independentBatches does
+// not detect or serialize on Source.
+func independentBatches(mounts []Mount) [][]Mount {
+	sorted := make([]Mount, len(mounts))
+	copy(sorted, mounts)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Target < sorted[j].Target })
+
+	var batches [][]Mount
+	for _, m := range sorted {
+		placed := false
+		for i := range batches {
+			if !targetConflicts(m.Target, batches[i]) {
+				batches[i] = append(batches[i], m)
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			batches = append(batches, []Mount{m})
+		}
+	}
+	return batches
+}
+
+func targetConflicts(target string, batch []Mount) bool {
+	for _, m := range batch {
+		if nestedPaths(target, m.Target) {
+			return true
+		}
+	}
+	return false
+}
+
+// nestedPaths reports whether a and b are the same path, or one is an
+// ancestor directory of the other.
+func nestedPaths(a, b string) bool {
+	a = filepath.Clean(a)
+	b = filepath.Clean(b)
+	if a == b {
+		return true
+	}
+	sep := string(filepath.Separator)
+	return strings.HasPrefix(a, strings.TrimSuffix(b, sep)+sep) || strings.HasPrefix(b, strings.TrimSuffix(a, sep)+sep)
+}
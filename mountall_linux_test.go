@@ -0,0 +1,59 @@
+package containerd
+
+import "testing"
+
+func TestNestedPaths(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want bool
+	}{
+		{"/a", "/a", true},
+		{"/a", "/a/b", true},
+		{"/a/b", "/a", true},
+		{"/a", "/ab", false},
+		{"/a", "/b", false},
+	}
+	for _, c := range cases {
+		if got := nestedPaths(c.a, c.b); got != c.want {
+			t.Errorf("nestedPaths(%q, %q) = %v, want %v", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestIndependentBatches(t *testing.T) {
+	mounts := []Mount{
+		{Target: "/a"},
+		{Target: "/a/b"},
+		{Target: "/c"},
+	}
+
+	batches := independentBatches(mounts)
+	if len(batches) != 2 {
+		t.Fatalf("got %d batches, want 2: %+v", len(batches), batches)
+	}
+
+	// /a and /a/b conflict and must land in different (ordered) batches;
+	// /c is independent of both and should share a batch with /a.
+	first := batches[0]
+	if len(first) != 2 {
+		t.Fatalf("first batch = %+v, want 2 independent targets", first)
+	}
+	for _, m := range first {
+		if m.Target == "/a/b" {
+			t.Fatalf("/a/b placed in the same batch as /a: %+v", first)
+		}
+	}
+
+	second := batches[1]
+	if len(second) != 1 || second[0].Target != "/a/b" {
+		t.Fatalf("second batch = %+v, want just /a/b", second)
+	}
+}
+
+func TestIndependentBatchesAllIndependent(t *testing.T) {
+	mounts := []Mount{{Target: "/a"}, {Target: "/b"}, {Target: "/c"}}
+	batches := independentBatches(mounts)
+	if len(batches) != 1 || len(batches[0]) != 3 {
+		t.Fatalf("got %+v, want a single batch of all 3 mounts", batches)
+	}
+}
@@ -0,0 +1,157 @@
+package containerd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// errOpenat2NotSupported is returned by mountedOpenat2 when the
+// openat2(2) syscall is not available (Linux < 5.6) or disabled by
+// seccomp, signalling callers to fall back to a slower check.
+var errOpenat2NotSupported = errors.New("containerd: openat2 not supported")
+
+// Mounted reports whether path is itself a mount point. It tries, in
+// order of decreasing speed and increasing compatibility: an
+// openat2(RESOLVE_NO_XDEV) probe (Linux >= 5.6), a stat-based
+// device-number comparison against the parent directory, and finally a
+// full scan of /proc/self/mountinfo.
+func Mounted(path string) (bool, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return false, err
+	}
+	if path == string(os.PathSeparator) {
+		return true, nil
+	}
+
+	mounted, err := mountedOpenat2(path)
+	if err == nil {
+		return mounted, nil
+	}
+	if err != errOpenat2NotSupported {
+		return false, err
+	}
+
+	mounted, err = mountedStat(path)
+	if err == nil {
+		return mounted, nil
+	}
+
+	return mountedMountinfo(path)
+}
+
+// mountedOpenat2 uses RESOLVE_NO_XDEV to detect, in a single syscall,
+// whether the last component of path crosses into a different mount
+// than its parent directory.
+func mountedOpenat2(path string) (bool, error) {
+	dir, last := filepath.Split(path)
+
+	dirFd, err := unix.Openat(unix.AT_FDCWD, dir, unix.O_PATH, 0)
+	if err != nil {
+		return false, err
+	}
+	defer unix.Close(dirFd)
+
+	how := unix.OpenHow{
+		Flags:   unix.O_PATH,
+		Resolve: unix.RESOLVE_NO_XDEV,
+	}
+	fd, err := unix.Openat2(dirFd, last, &how)
+	switch err {
+	case nil:
+		unix.Close(fd)
+		return false, nil
+	case unix.EXDEV:
+		// RESOLVE_NO_XDEV refused to cross into a different mount: path
+		// is a mount point.
+		return true, nil
+	case unix.ENOSYS, unix.EPERM:
+		// ENOSYS: openat2 isn't implemented (Linux < 5.6). EPERM: a
+		// seccomp filter (e.g. Docker's default profile) denied the
+		// syscall outright, which looks identical to "not available" as
+		// far as Mounted's fallback chain is concerned.
+		return false, errOpenat2NotSupported
+	default:
+		return false, err
+	}
+}
+
+// mountedStat compares the device number of path with that of its
+// parent directory; a difference means path is a mount point. It
+// cannot, by itself, detect a bind mount of one directory over another
+// on the same filesystem, which is why mountedOpenat2 is preferred when
+// available.
+func mountedStat(path string) (bool, error) {
+	var st, pst unix.Stat_t
+
+	if err := unix.Lstat(path, &st); err != nil {
+		return false, err
+	}
+	if err := unix.Lstat(filepath.Dir(path), &pst); err != nil {
+		return false, err
+	}
+	return st.Dev != pst.Dev, nil
+}
+
+// mountedMountinfo is the slow-path fallback: it scans
+// /proc/self/mountinfo for an exact Mountpoint match.
+func mountedMountinfo(path string) (bool, error) {
+	found := false
+	_, err := GetMounts(func(m *MountInfo) (skip, stop bool) {
+		if m.Mountpoint == path {
+			found = true
+			return false, true
+		}
+		return true, false
+	})
+	if err != nil {
+		return false, err
+	}
+	return found, nil
+}
+
+// Unmount calls the unmount(2) syscall, retrying on EINTR.
+func Unmount(target string, flags int) error {
+	for {
+		err := unix.Unmount(target, flags)
+		if err != unix.EINTR {
+			return err
+		}
+	}
+}
+
+// UnmountAll unmounts target and, first, every mount nested beneath it,
+// deepest first, using a lazy (MNT_DETACH) unmount for each so that
+// busy mounts do not abort the whole operation.
+func UnmountAll(target string, flags int) error {
+	target, err := filepath.Abs(target)
+	if err != nil {
+		return err
+	}
+
+	mounts, err := GetMounts(func(m *MountInfo) (skip, stop bool) {
+		skip = m.Mountpoint != target && !strings.HasPrefix(m.Mountpoint, strings.TrimSuffix(target, string(os.PathSeparator))+string(os.PathSeparator))
+		return skip, false
+	})
+	if err != nil {
+		return err
+	}
+
+	// Unmount the deepest mount points first so that parents are never
+	// torn down before their children.
+	sort.Slice(mounts, func(i, j int) bool {
+		return len(mounts[i].Mountpoint) > len(mounts[j].Mountpoint)
+	})
+
+	for _, m := range mounts {
+		if err := Unmount(m.Mountpoint, flags|unix.MNT_DETACH); err != nil && !errors.Is(err, unix.EINVAL) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package containerd
+
+// MountInfo reflects one line in /proc/<pid>/mountinfo. See
+// http://man7.org/linux/man-pages/man5/proc.5.html for the format of
+// this file.
+type MountInfo struct {
+	// ID is a unique identifier of the mount (may be reused after umount).
+	ID int
+
+	// Parent is the ID of the parent mount (or of self for the root of
+	// the mount tree).
+	Parent int
+
+	// Major is the first half of the device ID for files on this
+	// filesystem, as reported in the st_dev field of stat(2).
+	Major int
+
+	// Minor is the second half of the device ID for files on this
+	// filesystem, as reported in the st_dev field of stat(2).
+	Minor int
+
+	// Root is the path of the directory in the filesystem which forms
+	// the root of this mount.
+	Root string
+
+	// Mountpoint is the path of the mount point relative to the
+	// process's root.
+	Mountpoint string
+
+	// Options is the per-mount options.
+	Options string
+
+	// Optional is zero or more fields of the form "tag[:value]", used
+	// for optional mount propagation information.
+	Optional string
+
+	// FSType is the filesystem type, e.g. "ext4".
+	FSType string
+
+	// Source is filesystem specific information, or "none".
+	Source string
+
+	// VFSOptions is the per-superblock options.
+	VFSOptions string
+}
+
+// FilterFunc is called once per MountInfo entry parsed by GetMounts or
+// GetMountsFromReader. If skip is true the entry is omitted from the
+// result. If stop is true, parsing stops after this entry (skip is
+// still honored for the current entry).
+type FilterFunc func(*MountInfo) (skip, stop bool)
@@ -0,0 +1,160 @@
+package containerd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// GetMounts retrieves a list of mounts for the current running process,
+// optionally filtered by filter. If filter is nil, all mounts are
+// returned.
+func GetMounts(filter FilterFunc) ([]*MountInfo, error) {
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return GetMountsFromReader(f, filter)
+}
+
+// GetMountsFromReader retrieves a list of mounts from r, which is
+// expected to be in the same format as /proc/<pid>/mountinfo,
+// optionally filtered by filter.
+func GetMountsFromReader(r io.Reader, filter FilterFunc) ([]*MountInfo, error) {
+	s := bufio.NewScanner(r)
+	var out []*MountInfo
+	for s.Scan() {
+		/*
+		   See http://man7.org/linux/man-pages/man5/proc.5.html
+
+		   36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+		   (1)(2)(3)   (4)   (5)      (6)      (7)   (8) (9)   (10)         (11)
+
+		   (1) mount ID: unique identifier of the mount (may be reused after umount)
+		   (2) parent ID: ID of parent (or of self for the top of the mount tree)
+		   (3) major:minor: value of st_dev for files on filesystem
+		   (4) root: root of the mount within the filesystem
+		   (5) mount point: mount point relative to the process's root
+		   (6) mount options: per mount options
+		   (7) optional fields: zero or more fields of the form "tag[:value]"
+		   (8) separator: marks the end of the optional fields
+		   (9) filesystem type: name of filesystem of the form "type[.subtype]"
+		   (10) mount source: filesystem specific information, or "none"
+		   (11) super options: per super block options
+		*/
+		text := s.Text()
+		fields := strings.Split(text, " ")
+		numFields := len(fields)
+		if numFields < 10 {
+			return nil, fmt.Errorf("parsing mountinfo line %q failed: not enough fields (%d)", text, numFields)
+		}
+
+		p := &MountInfo{}
+
+		var err error
+		p.ID, err = strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		p.Parent, err = strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		mm := strings.SplitN(fields[2], ":", 2)
+		if len(mm) != 2 {
+			return nil, fmt.Errorf("parsing mountinfo line %q failed: invalid major:minor field %q", text, fields[2])
+		}
+		if p.Major, err = strconv.Atoi(mm[0]); err != nil {
+			return nil, err
+		}
+		if p.Minor, err = strconv.Atoi(mm[1]); err != nil {
+			return nil, err
+		}
+
+		if p.Root, err = unescapeMountField(fields[3]); err != nil {
+			return nil, fmt.Errorf("parsing mountinfo line %q failed: %w", text, err)
+		}
+		if p.Mountpoint, err = unescapeMountField(fields[4]); err != nil {
+			return nil, fmt.Errorf("parsing mountinfo line %q failed: %w", text, err)
+		}
+		p.Options = fields[5]
+
+		// Zero or more optional fields, terminated by a "-" separator.
+		i := 6
+		for ; i < numFields && fields[i] != "-"; i++ {
+			if i > 6 {
+				p.Optional += " "
+			}
+			p.Optional += fields[i]
+		}
+		if i == numFields || fields[i] != "-" {
+			return nil, fmt.Errorf("parsing mountinfo line %q failed: missing separator", text)
+		}
+		i++
+		if i+3 != numFields {
+			return nil, fmt.Errorf("parsing mountinfo line %q failed: unexpected number of fields after separator", text)
+		}
+
+		p.FSType = fields[i]
+		i++
+		if p.Source, err = unescapeMountField(fields[i]); err != nil {
+			return nil, fmt.Errorf("parsing mountinfo line %q failed: %w", text, err)
+		}
+		i++
+		p.VFSOptions = fields[i]
+
+		// The filter runs only after every field has been parsed, so
+		// predicates on e.g. FSType or Source see a fully populated entry.
+		var skip, stop bool
+		if filter != nil {
+			skip, stop = filter(p)
+		}
+		if !skip {
+			out = append(out, p)
+		}
+		if stop {
+			break
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// unescapeMountField undoes the octal escaping mountinfo applies to
+// whitespace and backslashes in path fields: "\040" (space), "\011"
+// (tab), "\012" (newline), and "\134" (backslash).
+func unescapeMountField(field string) (string, error) {
+	var buf []byte
+	for i := 0; i < len(field); i++ {
+		if field[i] != '\\' {
+			if buf != nil {
+				buf = append(buf, field[i])
+			}
+			continue
+		}
+		if i+4 > len(field) {
+			return "", fmt.Errorf("invalid escape sequence in %q", field)
+		}
+		v, err := strconv.ParseUint(field[i+1:i+4], 8, 8)
+		if err != nil {
+			return "", fmt.Errorf("invalid escape sequence in %q: %w", field, err)
+		}
+		if buf == nil {
+			buf = make([]byte, 0, len(field))
+			buf = append(buf, field[:i]...)
+		}
+		buf = append(buf, byte(v))
+		i += 3
+	}
+	if buf == nil {
+		return field, nil
+	}
+	return string(buf), nil
+}
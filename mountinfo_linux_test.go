@@ -0,0 +1,87 @@
+package containerd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetMountsFromReader(t *testing.T) {
+	const mountinfo = `36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+36 35 98:0 /mnt\0401 /mnt\0402 rw - ext3 /dev/root rw
+`
+	infos, err := GetMountsFromReader(strings.NewReader(mountinfo), nil)
+	if err != nil {
+		t.Fatalf("GetMountsFromReader: %v", err)
+	}
+	if len(infos) != 2 {
+		t.Fatalf("got %d mounts, want 2", len(infos))
+	}
+
+	first := infos[0]
+	if first.ID != 36 || first.Parent != 35 {
+		t.Errorf("first.ID/Parent = %d/%d, want 36/35", first.ID, first.Parent)
+	}
+	if first.Major != 98 || first.Minor != 0 {
+		t.Errorf("first.Major/Minor = %d/%d, want 98/0", first.Major, first.Minor)
+	}
+	if first.Root != "/mnt1" || first.Mountpoint != "/mnt2" {
+		t.Errorf("first.Root/Mountpoint = %q/%q, want /mnt1 //mnt2", first.Root, first.Mountpoint)
+	}
+	if first.FSType != "ext3" || first.Source != "/dev/root" {
+		t.Errorf("first.FSType/Source = %q/%q, want ext3 //dev/root", first.FSType, first.Source)
+	}
+
+	second := infos[1]
+	if second.Root != "/mnt 1" || second.Mountpoint != "/mnt 2" {
+		t.Errorf("second.Root/Mountpoint = %q/%q, want unescaped spaces", second.Root, second.Mountpoint)
+	}
+}
+
+func TestGetMountsFromReaderFilter(t *testing.T) {
+	const mountinfo = `36 35 98:0 / /mnt1 rw - ext3 /dev/root rw
+37 35 98:0 / /mnt2 rw - tmpfs none rw
+`
+	infos, err := GetMountsFromReader(strings.NewReader(mountinfo), func(mi *MountInfo) (skip, stop bool) {
+		return mi.FSType == "tmpfs", false
+	})
+	if err != nil {
+		t.Fatalf("GetMountsFromReader: %v", err)
+	}
+	if len(infos) != 1 || infos[0].Mountpoint != "/mnt1" {
+		t.Fatalf("filter did not skip tmpfs entry: %+v", infos)
+	}
+}
+
+func TestGetMountsFromReaderShortLine(t *testing.T) {
+	if _, err := GetMountsFromReader(strings.NewReader("36 35 98:0\n"), nil); err == nil {
+		t.Fatal("expected error for too few fields, got nil")
+	}
+}
+
+func TestUnescapeMountField(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{`/mnt1`, `/mnt1`},
+		{`/mnt\0401`, `/mnt 1`},
+		{`/mnt\0111`, "/mnt\t1"},
+		{`/mnt\0121`, "/mnt\n1"},
+		{`/mnt\1341`, `/mnt\1`},
+	}
+	for _, c := range cases {
+		got, err := unescapeMountField(c.in)
+		if err != nil {
+			t.Errorf("unescapeMountField(%q): %v", c.in, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("unescapeMountField(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestUnescapeMountFieldInvalid(t *testing.T) {
+	if _, err := unescapeMountField(`/mnt\09`); err == nil {
+		t.Fatal("expected error for truncated escape sequence, got nil")
+	}
+}
@@ -0,0 +1,31 @@
+//go:build !linux
+// +build !linux
+
+package containerd
+
+import "io"
+
+// GetMounts is not supported on this platform.
+func GetMounts(filter FilterFunc) ([]*MountInfo, error) {
+	return nil, ErrNotSupported
+}
+
+// GetMountsFromReader is not supported on this platform.
+func GetMountsFromReader(r io.Reader, filter FilterFunc) ([]*MountInfo, error) {
+	return nil, ErrNotSupported
+}
+
+// Mounted is not supported on this platform.
+func Mounted(path string) (bool, error) {
+	return false, ErrNotSupported
+}
+
+// Unmount is not supported on this platform.
+func Unmount(target string, flags int) error {
+	return ErrNotSupported
+}
+
+// UnmountAll is not supported on this platform.
+func UnmountAll(target string, flags int) error {
+	return ErrNotSupported
+}
@@ -0,0 +1,46 @@
+// Package rootfs provides helpers for assembling a container's root
+// filesystem out of a base directory and a set of additional mounts.
+package rootfs
+
+// Options holds the settings accumulated from an Option chain.
+type Options struct {
+	// Console, when set, is bind-mounted to /dev/console inside the new
+	// root once the mount namespace has been switched into.
+	Console string
+
+	// Readonly remounts rootfs read-only after the initial bind mount.
+	Readonly bool
+
+	// NoPivotRoot skips pivot_root and instead leaves "/" mounted
+	// MS_SLAVE, for environments (e.g. some chrooted CI runners) where
+	// pivot_root is unavailable.
+	NoPivotRoot bool
+}
+
+// Option mutates an Options while setting up a rootfs. Options are
+// applied in the order they are passed to SetupRootfs.
+type Option func(*Options)
+
+// WithConsole bind-mounts console to /dev/console inside the new root.
+func WithConsole(console string) Option {
+	return func(o *Options) {
+		o.Console = console
+	}
+}
+
+// WithReadonly remounts the rootfs read-only after it is bind-mounted
+// onto itself.
+func WithReadonly() Option {
+	return func(o *Options) {
+		o.Readonly = true
+	}
+}
+
+// WithNoPivotRoot makes SetupRootfs mark "/" MS_SLAVE instead of
+// MS_PRIVATE and skip the pivot_root step, leaving the caller's mount
+// namespace entered but not rooted at rootfs.
+func WithNoPivotRoot() Option {
+	return func(o *Options) {
+		o.NoPivotRoot = true
+	}
+}
@@ -0,0 +1,167 @@
+package rootfs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/docker/containerd"
+	"golang.org/x/sys/unix"
+)
+
+// defaultMountFlags are applied to every caller-supplied mount in
+// addition to whatever options it already carries, matching the
+// conservative defaults other container runtimes use for rootfs
+// submounts.
+var defaultMountFlags = []string{"noexec", "nosuid", "nodev"}
+
+// SetupRootfs performs the canonical container rootfs setup: it
+// isolates the calling thread into a new mount namespace, makes
+// rootfs (and everything under it) a private mount point, layers the
+// caller-supplied mounts on top, and finally pivots into rootfs as the
+// new "/".
+//
+// SetupRootfs calls runtime.LockOSThread and never unlocks it: once a
+// thread's mount namespace has been changed, Go must never schedule
+// another goroutine onto it, so the thread is retired along with the
+// calling goroutine rather than returned to the scheduler's pool.
+// Callers that need to keep using their goroutine afterwards should
+// invoke SetupRootfs from a goroutine dedicated to that purpose.
+func SetupRootfs(rootfs string, mounts []containerd.Mount, opts ...Option) error {
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	runtime.LockOSThread()
+
+	if err := unix.Unshare(unix.CLONE_NEWNS); err != nil {
+		return fmt.Errorf("rootfs: unshare mount namespace: %w", err)
+	}
+
+	propagation := unix.MS_PRIVATE | unix.MS_REC
+	if o.NoPivotRoot {
+		propagation = unix.MS_SLAVE | unix.MS_REC
+	}
+	if err := unix.Mount("", "/", "", uintptr(propagation), ""); err != nil {
+		return fmt.Errorf("rootfs: make / a %s mount: %w", propagationName(o.NoPivotRoot), err)
+	}
+
+	if err := unix.Mount(rootfs, rootfs, "", unix.MS_BIND|unix.MS_REC, ""); err != nil {
+		return fmt.Errorf("rootfs: bind mount %s onto itself: %w", rootfs, err)
+	}
+
+	for _, m := range mounts {
+		target := filepath.Join(rootfs, m.Target)
+		if err := createMountpoint(target, m.Source); err != nil {
+			return fmt.Errorf("rootfs: create mount target %s: %w", target, err)
+		}
+		if err := withDefaultFlags(m).Mount(target); err != nil {
+			return fmt.Errorf("rootfs: mount %s onto %s: %w", m.Source, target, err)
+		}
+	}
+
+	if o.Console != "" {
+		console := filepath.Join(rootfs, "dev", "console")
+		if err := createMountpoint(console, o.Console); err != nil {
+			return fmt.Errorf("rootfs: create /dev/console: %w", err)
+		}
+		if err := unix.Mount(o.Console, console, "", unix.MS_BIND, ""); err != nil {
+			return fmt.Errorf("rootfs: bind mount console: %w", err)
+		}
+	}
+
+	// The read-only remount must be the last thing done to rootfs:
+	// every target directory/file created above for a submount, and
+	// /dev/console for the console bind, needs to still be writable while
+	// we're creating it.
+	if o.Readonly {
+		if err := unix.Mount(rootfs, rootfs, "", unix.MS_BIND|unix.MS_REMOUNT|unix.MS_RDONLY, ""); err != nil {
+			return fmt.Errorf("rootfs: remount %s read-only: %w", rootfs, err)
+		}
+	}
+
+	if o.NoPivotRoot {
+		return nil
+	}
+	return pivotRoot(rootfs)
+}
+
+// withDefaultFlags returns m with defaultMountFlags merged into its
+// Options, so that submounts are locked down even when the caller
+// didn't think to ask.
+func withDefaultFlags(m containerd.Mount) containerd.Mount {
+	options := append([]string{}, m.Options...)
+	for _, flag := range defaultMountFlags {
+		if !contains(options, flag) {
+			options = append(options, flag)
+		}
+	}
+	m.Options = options
+	return m
+}
+
+// createMountpoint prepares target to receive a bind mount from
+// source: a directory for a directory source or for a source that
+// cannot be stat'ed, or an empty regular file for anything else (most
+// commonly a single file such as /etc/resolv.conf) — mount(2) refuses
+// to bind a file onto a directory or vice versa.
+func createMountpoint(target, source string) error {
+	isDir := true
+	if fi, err := os.Stat(source); err == nil {
+		isDir = fi.IsDir()
+	}
+
+	if isDir {
+		return os.MkdirAll(target, 0711)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0711); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func contains(options []string, option string) bool {
+	for _, o := range options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+func propagationName(slave bool) string {
+	if slave {
+		return "MS_SLAVE"
+	}
+	return "MS_PRIVATE"
+}
+
+// pivotRoot makes rootfs the new "/" for the calling (mount-namespace
+// isolated) thread, unmounting the old root once the switch is
+// complete.
+func pivotRoot(rootfs string) error {
+	oldroot := filepath.Join(rootfs, ".oldroot")
+	if err := os.MkdirAll(oldroot, 0700); err != nil {
+		return fmt.Errorf("rootfs: create %s: %w", oldroot, err)
+	}
+
+	if err := unix.PivotRoot(rootfs, oldroot); err != nil {
+		return fmt.Errorf("rootfs: pivot_root %s: %w", rootfs, err)
+	}
+
+	if err := unix.Chdir("/"); err != nil {
+		return fmt.Errorf("rootfs: chdir to new root: %w", err)
+	}
+
+	if err := unix.Unmount("/.oldroot", unix.MNT_DETACH); err != nil {
+		return fmt.Errorf("rootfs: unmount /.oldroot: %w", err)
+	}
+	return nil
+}
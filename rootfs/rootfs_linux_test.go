@@ -0,0 +1,67 @@
+package rootfs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateMountpointDir(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "src")
+	if err := os.Mkdir(source, 0755); err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(dir, "nested", "target")
+
+	if err := createMountpoint(target, source); err != nil {
+		t.Fatalf("createMountpoint: %v", err)
+	}
+
+	fi, err := os.Stat(target)
+	if err != nil {
+		t.Fatalf("target not created: %v", err)
+	}
+	if !fi.IsDir() {
+		t.Fatalf("target is not a directory, want a directory to mirror a directory source")
+	}
+}
+
+func TestCreateMountpointFile(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "resolv.conf")
+	if err := os.WriteFile(source, []byte("nameserver 1.1.1.1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(dir, "nested", "resolv.conf")
+
+	if err := createMountpoint(target, source); err != nil {
+		t.Fatalf("createMountpoint: %v", err)
+	}
+
+	fi, err := os.Stat(target)
+	if err != nil {
+		t.Fatalf("target not created: %v", err)
+	}
+	if fi.IsDir() {
+		t.Fatalf("target is a directory, want an empty regular file to mirror a file source")
+	}
+	if fi.Size() != 0 {
+		t.Fatalf("target size = %d, want 0 (createMountpoint should not copy source's contents)", fi.Size())
+	}
+}
+
+func TestCreateMountpointMissingSource(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target")
+
+	// A source that doesn't exist yet (e.g. not bind-mounted into the
+	// caller's own mount namespace) falls back to creating a directory,
+	// matching the common case of a plain bind submount.
+	if err := createMountpoint(target, filepath.Join(dir, "does-not-exist")); err != nil {
+		t.Fatalf("createMountpoint: %v", err)
+	}
+	if fi, err := os.Stat(target); err != nil || !fi.IsDir() {
+		t.Fatalf("target = %v (err %v), want a directory", fi, err)
+	}
+}